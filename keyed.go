@@ -0,0 +1,154 @@
+package gomultirate
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// keyedEntry tracks one key's RateLimiter alongside its LRU bookkeeping.
+type keyedEntry struct {
+	key      string
+	limiter  *RateLimiter
+	lastUsed time.Time
+}
+
+// KeyedRateLimiter lazily creates a *RateLimiter per string key (e.g. per target host
+// or per API token) from a shared template of windows, evicting idle keys so
+// high-cardinality workloads (scanners, proxies, multi-tenant APIs) don't grow the
+// limiter's memory without bound.
+// Use NewKeyedRateLimiter to create an instance.
+type KeyedRateLimiter struct {
+	mu       sync.Mutex
+	template map[string]*Limit
+	maxKeys  int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List // front = most recently used, back = least recently used
+}
+
+// NewKeyedRateLimiter creates a KeyedRateLimiter that stamps out a *RateLimiter per key
+// from 'template'. At most 'maxKeys' keys are tracked at once, and a key idle for
+// longer than 'ttl' is evicted on the next access; 'ttl' <= 0 disables the idle eviction,
+// leaving 'maxKeys' as the only bound.
+func NewKeyedRateLimiter(template map[string]*Limit, maxKeys int, ttl time.Duration) (*KeyedRateLimiter, error) {
+	if len(template) == 0 {
+		return nil, errors.New("can't provide a keyed rate limiter with no limits")
+	}
+	if maxKeys <= 0 {
+		return nil, errors.New("maxKeys must be greater than zero")
+	}
+
+	return &KeyedRateLimiter{
+		template: template,
+		maxKeys:  maxKeys,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}, nil
+}
+
+// getLocked returns the RateLimiter for 'key', creating it from the template on first
+// use. The mutex must be held by the caller.
+func (k *KeyedRateLimiter) getLocked(key string) *RateLimiter {
+	k.evictExpiredLocked()
+
+	if el, ok := k.entries[key]; ok {
+		k.order.MoveToFront(el)
+		el.Value.(*keyedEntry).lastUsed = time.Now()
+		return el.Value.(*keyedEntry).limiter
+	}
+
+	limits := make(map[string]*Limit, len(k.template))
+	for name, lim := range k.template {
+		limits[name] = lim.clone()
+	}
+
+	// The template is guaranteed non-empty by NewKeyedRateLimiter, so this can't fail.
+	limiter, _ := NewRateLimiter(limits)
+
+	el := k.order.PushFront(&keyedEntry{key: key, limiter: limiter, lastUsed: time.Now()})
+	k.entries[key] = el
+
+	for k.order.Len() > k.maxKeys {
+		k.evictOldestLocked()
+	}
+
+	return limiter
+}
+
+// evictOldestLocked drops the least recently used key. The mutex must be held by the caller.
+func (k *KeyedRateLimiter) evictOldestLocked() {
+	el := k.order.Back()
+	if el == nil {
+		return
+	}
+
+	k.order.Remove(el)
+	delete(k.entries, el.Value.(*keyedEntry).key)
+}
+
+// evictExpiredLocked drops every key that's been idle longer than k.ttl.
+// The mutex must be held by the caller.
+func (k *KeyedRateLimiter) evictExpiredLocked() {
+	if k.ttl <= 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-k.ttl)
+	for {
+		el := k.order.Back()
+		if el == nil || el.Value.(*keyedEntry).lastUsed.After(cutoff) {
+			return
+		}
+
+		k.order.Remove(el)
+		delete(k.entries, el.Value.(*keyedEntry).key)
+	}
+}
+
+// Take blocks until every window for 'key' allows one more event, same as
+// RateLimiter.Wait, creating the key's limiter from the template if it's new.
+func (k *KeyedRateLimiter) Take(ctx context.Context, key string) error {
+	k.mu.Lock()
+	limiter := k.getLocked(key)
+	k.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
+
+// Try reports whether 'key' may proceed right now, without blocking, same as
+// RateLimiter.Try, creating the key's limiter from the template if it's new.
+func (k *KeyedRateLimiter) Try(key string) (bool, time.Duration) {
+	k.mu.Lock()
+	limiter := k.getLocked(key)
+	k.mu.Unlock()
+
+	return limiter.Try()
+}
+
+// WaitEvenly waits until 'window' (one of the template's window names) is free for
+// 'key', spacing calls evenly instead of allowing a burst. See RateLimiter.WaitEvenly.
+func (k *KeyedRateLimiter) WaitEvenly(ctx context.Context, key, window string) error {
+	k.mu.Lock()
+	limiter := k.getLocked(key)
+	k.mu.Unlock()
+
+	return limiter.WaitEvenly(ctx, window)
+}
+
+// Stats returns a snapshot of every window tracked for 'key'. It reports false without
+// creating the key if it hasn't been used yet.
+func (k *KeyedRateLimiter) Stats(key string) (map[string]WindowStats, bool) {
+	k.mu.Lock()
+	el, ok := k.entries[key]
+	k.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return el.Value.(*keyedEntry).limiter.stats(), true
+}
@@ -0,0 +1,100 @@
+package gomultirate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test that each key gets its own independent budget from the shared template.
+func TestKeyedRateLimiterPerKey(t *testing.T) {
+	template := map[string]*Limit{
+		"test": NewLimit(time.Second, 1),
+	}
+
+	keyed, err := NewKeyedRateLimiter(template, 10, 0)
+	if err != nil {
+		t.Fatalf("NewKeyedRateLimiter failed: %v", err)
+	}
+
+	if ok, _ := keyed.Try("host-a"); !ok {
+		t.Error("expected first Try for host-a to succeed")
+	}
+	if ok, _ := keyed.Try("host-a"); ok {
+		t.Error("expected second Try for host-a to fail, budget already spent")
+	}
+	if ok, _ := keyed.Try("host-b"); !ok {
+		t.Error("expected host-b to have its own independent budget")
+	}
+}
+
+// Test that the LRU eviction keeps the tracked key count bounded.
+func TestKeyedRateLimiterEviction(t *testing.T) {
+	template := map[string]*Limit{
+		"test": NewLimit(time.Second, 1),
+	}
+
+	keyed, err := NewKeyedRateLimiter(template, 2, 0)
+	if err != nil {
+		t.Fatalf("NewKeyedRateLimiter failed: %v", err)
+	}
+
+	keyed.Try("a")
+	keyed.Try("b")
+	keyed.Try("c") // should evict "a", the least recently used
+
+	if _, ok := keyed.Stats("a"); ok {
+		t.Error("expected \"a\" to have been evicted")
+	}
+	if _, ok := keyed.Stats("b"); !ok {
+		t.Error("expected \"b\" to still be tracked")
+	}
+	if _, ok := keyed.Stats("c"); !ok {
+		t.Error("expected \"c\" to still be tracked")
+	}
+}
+
+// Test that idle keys past the TTL are evicted on the next access.
+func TestKeyedRateLimiterTTL(t *testing.T) {
+	template := map[string]*Limit{
+		"test": NewLimit(time.Second, 1),
+	}
+
+	keyed, err := NewKeyedRateLimiter(template, 10, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewKeyedRateLimiter failed: %v", err)
+	}
+
+	keyed.Try("idle")
+	time.Sleep(100 * time.Millisecond)
+	keyed.Try("active") // triggers eviction of the now-expired "idle" key
+
+	if _, ok := keyed.Stats("idle"); ok {
+		t.Error("expected \"idle\" to have been evicted after its TTL elapsed")
+	}
+}
+
+// Test that Take spends a token and Stats reports the expected token counts.
+func TestKeyedRateLimiterTakeAndStats(t *testing.T) {
+	template := map[string]*Limit{
+		"test": NewLimit(time.Second, 1),
+	}
+
+	keyed, err := NewKeyedRateLimiter(template, 10, 0)
+	if err != nil {
+		t.Fatalf("NewKeyedRateLimiter failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := keyed.Take(ctx, "host"); err != nil {
+		t.Fatalf("expected Take to succeed immediately: %v", err)
+	}
+
+	stats, ok := keyed.Stats("host")
+	if !ok {
+		t.Fatal("expected stats for \"host\" to exist after Take")
+	}
+	if stats["test"].Tokens >= 1 {
+		t.Errorf("expected the \"test\" window to have spent its only token, got %v", stats["test"].Tokens)
+	}
+}
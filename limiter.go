@@ -3,73 +3,185 @@
 package gomultirate
 
 import (
+	"container/list"
 	"context"
 	"errors"
+	"math"
 	"sync"
 	"time"
 )
 
-// Limit represents a single rate limit window.
-// Use NewLimit to create a instance.
+// Rate represents how many tokens a Limit accumulates per second.
+type Rate float64
+
+// Inf is the infinite Rate. A Limit configured with Inf always has tokens
+// available and never blocks.
+const Inf Rate = math.MaxFloat64
+
+// Every converts a minimum time interval between events into a Rate.
+// Mirrors rate.Every from golang.org/x/time/rate.
+func Every(interval time.Duration) Rate {
+	if interval <= 0 {
+		return Inf
+	}
+	return Rate(time.Second) / Rate(interval)
+}
+
+// Limit represents a single rate limit window, backed by a token bucket.
+// Use NewLimit to create an instance.
 type Limit struct {
-	count       int
+	rate        Rate
+	burst       int
+	tokens      float64
+	last        time.Time
 	interval    time.Duration
-	lastReset   time.Time
 	limit       int
 	timeBetween time.Duration
+	nextEven    time.Time
 }
 
-// NewLimit create a new rate limit window.
+// NewLimit creates a new rate limit window.
 // 'interval' is the total window time and 'limit' the max amount of requests in that interval.
+// The window starts full: up to 'limit' requests can burst immediately, and tokens
+// then refill continuously so that 'limit' requests are available every 'interval'.
 func NewLimit(interval time.Duration, limit int) *Limit {
 	if limit <= 0 {
 		panic("limit must be greater than zero")
 	}
 
+	now := time.Now()
 	return &Limit{
-		count:       0,
+		rate:        Every(interval / time.Duration(limit)),
+		burst:       limit,
+		tokens:      float64(limit),
+		last:        now,
 		interval:    interval,
-		lastReset:   time.Now(),
 		limit:       limit,
 		timeBetween: interval / time.Duration(limit),
+		nextEven:    now,
 	}
 }
 
-// checkLimit try to reset the limit and return if it's available.
-func (l *Limit) checkLimit() bool {
+// NewLimitAt creates a rate limit window directly from a Rate and a burst size.
+// Use Inf for a window that should never block.
+func NewLimitAt(rate Rate, burst int) *Limit {
+	if burst <= 0 {
+		panic("burst must be greater than zero")
+	}
+
 	now := time.Now()
-	if time.Since(l.lastReset) >= l.interval {
-		l.count = 0
-		l.lastReset = now
+	return &Limit{
+		rate:     rate,
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     now,
+		limit:    burst,
+		nextEven: now,
 	}
-	return l.count < l.limit
 }
 
-// getRemainingTime gets how much time until the next reset.
+// advance returns the token count the window would have at 'now', without mutating it.
 // The mutex must be held by the caller.
-func (l *Limit) getRemainingTime() time.Duration {
-	// It's already free to use.
-	if l.count < l.limit {
-		return 0
+func (l *Limit) advance(now time.Time) float64 {
+	if l.rate == Inf {
+		return float64(l.burst)
 	}
 
-	// Verify how much time has elapsed since the last reset.
-	elapsed := time.Since(l.lastReset)
+	elapsed := now.Sub(l.last)
+	if elapsed < 0 {
+		elapsed = 0
+	}
 
-	// Return how much until the next reset.
-	remaining := l.interval - elapsed
-	if remaining < 0 {
-		return 0
+	tokens := l.tokens + elapsed.Seconds()*float64(l.rate)
+	if tokens > float64(l.burst) {
+		tokens = float64(l.burst)
+	}
+	return tokens
+}
+
+// reserveLocked consumes n tokens as of 'now', even if that drives the balance negative.
+// It reports whether the tokens were immediately available and, if not, how long until
+// they would be. The mutex must be held by the caller.
+func (l *Limit) reserveLocked(now time.Time, n int) (ok bool, wait time.Duration) {
+	if l.rate == Inf {
+		return true, 0
+	}
+
+	tokens := l.advance(now) - float64(n)
+	l.tokens = tokens
+	l.last = now
+
+	if tokens >= 0 {
+		return true, 0
+	}
+
+	wait = time.Duration(-tokens / float64(l.rate) * float64(time.Second))
+	return false, wait
+}
+
+// restoreLocked returns n tokens to the window, capped at its burst size.
+// The mutex must be held by the caller.
+func (l *Limit) restoreLocked(now time.Time, n int) {
+	if l.rate == Inf {
+		return
+	}
+
+	tokens := l.advance(now) + float64(n)
+	if tokens > float64(l.burst) {
+		tokens = float64(l.burst)
 	}
-	return remaining
+	l.tokens = tokens
+	l.last = now
+}
+
+// clone returns a fresh window with the same configured rate and burst as l, but its
+// own token balance and timers starting from now. Used by KeyedRateLimiter to stamp
+// out a RateLimiter per key from a shared template.
+func (l *Limit) clone() *Limit {
+	now := time.Now()
+	return &Limit{
+		rate:        l.rate,
+		burst:       l.burst,
+		tokens:      float64(l.burst),
+		last:        now,
+		interval:    l.interval,
+		limit:       l.limit,
+		timeBetween: l.timeBetween,
+		nextEven:    now,
+	}
+}
+
+// reconfigureLocked swaps this window's interval and limit, and refills it back to a
+// full burst under the new parameters. The mutex must be held by the caller. This is
+// unexported and reached only through RateLimiter.SleepAndReset: a *Limit can be
+// shared with a running RateLimiter, and nothing outside the package can take the
+// RateLimiter's lock, so a public Reconfigure would let a caller race every other
+// method on the same window.
+func (l *Limit) reconfigureLocked(interval time.Duration, limit int) {
+	if limit <= 0 {
+		panic("limit must be greater than zero")
+	}
+
+	now := time.Now()
+	l.rate = Every(interval / time.Duration(limit))
+	l.burst = limit
+	l.tokens = float64(limit)
+	l.last = now
+	l.interval = interval
+	l.limit = limit
+	l.timeBetween = interval / time.Duration(limit)
+	l.nextEven = now
 }
 
-// rateLimiter is the main Rate Limiter implementation.
+// RateLimiter is the main Rate Limiter implementation.
 // Created through NewRateLimiter.
 // Provide a map of limit windows and a mutex for concurrency.
 type RateLimiter struct {
-	limits map[string]*Limit
-	mu     sync.Mutex
+	limits      map[string]*Limit
+	mu          sync.Mutex
+	pausedUntil time.Time
+	wake        chan struct{}
+	queue       *list.List // FIFO of *waiter, oldest arrival at the front
 }
 
 // NewRateLimiter creates the rate limiter with the provided map of limits.
@@ -81,175 +193,485 @@ func NewRateLimiter(limits map[string]*Limit) (*RateLimiter, error) {
 
 	return &RateLimiter{
 		limits: limits,
+		wake:   make(chan struct{}),
+		queue:  list.New(),
 	}, nil
 }
 
-// allowAndIncrement verify if the limits are available, if they are, consume them.
+// broadcastLocked wakes every goroutine currently parked in Wait/WaitN/WaitEvenly so
+// they re-read pausedUntil and the (possibly just reconfigured) window parameters.
 // The mutex must be held by the caller.
-func (r *RateLimiter) allowAndIncrement() bool {
-	// Check all windows
-	for _, win := range r.limits {
-		if !win.checkLimit() {
-			return false
-		}
+func (r *RateLimiter) broadcastLocked() {
+	close(r.wake)
+	r.wake = make(chan struct{})
+}
+
+// SleepAndReset pauses every pending and future Wait/WaitN/WaitEvenly caller for 'sleep',
+// then swaps the named window's interval/limit and resets its counters. Intended for
+// callers that receive an HTTP 429 with a Retry-After header and need to recalibrate
+// the limiter to match the server's real budget instead of racing it.
+func (r *RateLimiter) SleepAndReset(sleep time.Duration, key string, newInterval time.Duration, newLimit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lim, exists := r.limits[key]
+	if !exists {
+		return errors.New("limiter doesn't exist")
 	}
 
-	// All limits available, increment counters
-	r.incrementCounts()
-	return true
+	r.pausedUntil = time.Now().Add(sleep)
+	lim.reconfigureLocked(newInterval, newLimit)
+	r.broadcastLocked()
+
+	return nil
 }
 
-// getMinWaitTime calculate the minimum wait time necessary for all windows to be reseted.
-// The mutex must be held by the caller.
-func (r *RateLimiter) getMinWaitTime() time.Duration {
-	var minWaitTime time.Duration
+// errReconfigured is returned internally by waitTurn when a wake signal fires
+// mid-wait, telling the caller to recompute its reservation against the new
+// parameters rather than treating it as a real failure.
+var errReconfigured = errors.New("gomultirate: limiter reconfigured")
+
+// closedChan is always ready; a waiter with no predecessor in the queue uses it
+// as its promotion signal so it can arm its timer immediately.
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// waiter is one caller parked in a RateLimiter's FIFO queue. promoted is closed
+// once this waiter becomes the head of the queue, either by the departing head or,
+// for a waiter that joins an empty queue, up front (closedChan), handing it the
+// responsibility of arming the next timer.
+type waiter struct {
+	timeToAct time.Time
+	promoted  chan struct{}
+}
 
-	// Go through each limit and get the remaining time.
-	for _, lim := range r.limits {
-		waitTime := lim.getRemainingTime()
+// waitTurn blocks until w is promoted to the head of the FIFO queue and its time
+// arrives, ctx is done, or wake fires. Only the head of the queue ever arms a
+// timer; when it fires or the head gives up, the next waiter in arrival order is
+// promoted and arms its own. This avoids every blocked caller racing a separate
+// timer against the same lock. The caller is responsible for pushing w onto the
+// queue beforehand and removing it afterward (see leaveQueue).
+func (r *RateLimiter) waitTurn(ctx context.Context, w *waiter, wake <-chan struct{}) error {
+	select {
+	case <-w.promoted:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wake:
+		return errReconfigured
+	}
 
-		// We get the highest wait time between the limits.
-		// This one is the minimum wait time to proceed with the execution.
-		if waitTime > 0 && (minWaitTime == 0 || waitTime < minWaitTime) {
-			minWaitTime = waitTime
+	// A reconfigure may have closed wake in the same instant this waiter was
+	// promoted; if both were ready, select could have picked promoted and left
+	// us about to act on a stale, pre-reconfigure reservation. Prefer wake.
+	select {
+	case <-wake:
+		return errReconfigured
+	default:
+	}
+
+	d := time.Until(w.timeToAct)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wake:
+		return errReconfigured
+	}
+}
+
+// leaveQueue removes 'el' from the FIFO queue. If 'el' was at the head, the next
+// waiter in line, if any, is promoted and may now arm its own timer.
+func (r *RateLimiter) leaveQueue(el *list.Element) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wasHead := r.queue.Front() == el
+	r.queue.Remove(el)
+
+	if wasHead {
+		if front := r.queue.Front(); front != nil {
+			close(front.Value.(*waiter).promoted)
 		}
 	}
+}
 
-	return minWaitTime
+// sleepUntil blocks until 'until', ctx is done, or 'wake' fires. A fired 'wake' simply
+// means the caller should re-read the limiter's current state and try again.
+func sleepUntil(ctx context.Context, until time.Time, wake <-chan struct{}) error {
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// incrementCounts consume one of each window limit.
-// The mutex must be held by the caller.
-func (r *RateLimiter) incrementCounts() {
-	for _, win := range r.limits {
-		win.count++
+// Reservation is the result of ReserveN: it tells the caller how long to wait
+// before acting on n reserved tokens across every window of a RateLimiter.
+// The tokens are consumed as soon as the Reservation is created; call Cancel
+// if the caller gives up before acting on it so the tokens aren't wasted.
+type Reservation struct {
+	ok        bool
+	limiter   *RateLimiter
+	n         int
+	timeToAct time.Time
+	windows   []*Limit
+}
+
+// OK reports whether the reservation is possible at all, i.e. n does not
+// exceed the burst of any window. A Reservation with OK() == false should
+// never be acted upon.
+func (res *Reservation) OK() bool {
+	return res.ok
+}
+
+// Delay is shorthand for DelayFrom(time.Now()).
+func (res *Reservation) Delay() time.Duration {
+	return res.DelayFrom(time.Now())
+}
+
+// DelayFrom reports how long the caller must wait, from 'now', before acting on the reservation.
+func (res *Reservation) DelayFrom(now time.Time) time.Duration {
+	if !res.ok {
+		return 0
 	}
+
+	delay := res.timeToAct.Sub(now)
+	if delay < 0 {
+		return 0
+	}
+	return delay
 }
 
-// Try gets the limit without blocking.
-// Returns true/false depending on if the limit is available.
-// If not, returns the time until the next reset.
-func (r *RateLimiter) Try() (bool, time.Duration) {
+// Cancel is shorthand for CancelAt(time.Now()).
+func (res *Reservation) Cancel() {
+	res.CancelAt(time.Now())
+}
+
+// CancelAt returns the reserved tokens to every window, as of 'now'. It's safe to call
+// even if the reservation already came due; tokens are restored relative to the
+// window's state at 'now', the same way Wait would have consumed them.
+func (res *Reservation) CancelAt(now time.Time) {
+	if !res.ok || res.limiter == nil {
+		return
+	}
+
+	res.limiter.mu.Lock()
+	defer res.limiter.mu.Unlock()
+
+	for _, win := range res.windows {
+		win.restoreLocked(now, res.n)
+	}
+}
+
+// reserveLockedAll consumes n tokens from every window as of 'now', mirroring
+// ReserveN's semantics. It reports whether n was even possible (i.e. doesn't
+// exceed any window's burst) and, if so, the windows touched and the longest
+// wait among them. The mutex must be held by the caller.
+func (r *RateLimiter) reserveLockedAll(now time.Time, n int) (ok bool, windows []*Limit, maxWait time.Duration) {
+	for _, lim := range r.limits {
+		if lim.rate != Inf && n > lim.burst {
+			return false, nil, 0
+		}
+	}
+
+	windows = make([]*Limit, 0, len(r.limits))
+	for _, lim := range r.limits {
+		_, wait := lim.reserveLocked(now, n)
+		windows = append(windows, lim)
+		if wait > maxWait {
+			maxWait = wait
+		}
+	}
+	return true, windows, maxWait
+}
+
+// ReserveN reserves n events across every window and reports when they may proceed.
+// Tokens are consumed immediately, even if they're not available yet; call
+// Reservation.Cancel to return them if the caller abandons the wait. If a
+// SleepAndReset pause is in effect, timeToAct is pushed out to at least the end
+// of the pause, so TryN/Try (built on ReserveN) stay paused too instead of only
+// blocking callers noticing the pause.
+func (r *RateLimiter) ReserveN(now time.Time, n int) *Reservation {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	limit := r.allowAndIncrement()
-	if limit {
-		return true, 0
+	ok, windows, maxWait := r.reserveLockedAll(now, n)
+	if !ok {
+		return &Reservation{ok: false}
+	}
+
+	timeToAct := now.Add(maxWait)
+	if r.pausedUntil.After(timeToAct) {
+		timeToAct = r.pausedUntil
 	}
 
-	// Get how much time until the next reset and return it.
-	waitTime := r.getMinWaitTime()
-	return false, waitTime
+	return &Reservation{
+		ok:        true,
+		limiter:   r,
+		n:         n,
+		timeToAct: timeToAct,
+		windows:   windows,
+	}
 }
 
-// Wait waits for all the limit windows to be available.
-// Receive a context for handling timeouts.
-func (r *RateLimiter) Wait(ctx context.Context) error {
-	// Get the lock.
+// AllowN reports whether n events may happen now across every window, consuming
+// tokens from each if so. Unlike ReserveN it never leaves windows in debt: if any
+// window can't afford n tokens, none of them are touched. It also fails while a
+// SleepAndReset pause is in effect, the same as a blocking Wait would.
+func (r *RateLimiter) AllowN(n int) bool {
 	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	// If it's free to use, just unlock and return.
-	if r.allowAndIncrement() {
-		r.mu.Unlock()
-		return nil
+	now := time.Now()
+	if r.pausedUntil.After(now) {
+		return false
 	}
 
-	// Calculate how much time until the next reset.
-	waitTime := r.getMinWaitTime()
+	reserved := make([]*Limit, 0, len(r.limits))
+	for _, lim := range r.limits {
+		ok, _ := lim.reserveLocked(now, n)
+		if !ok {
+			lim.restoreLocked(now, n)
+			for _, done := range reserved {
+				done.restoreLocked(now, n)
+			}
+			return false
+		}
+		reserved = append(reserved, lim)
+	}
+	return true
+}
 
-	// Unlock since it will wait.
-	r.mu.Unlock()
+// TryN gets n units of every window without blocking.
+// Returns true/false depending on if they're available.
+// If not, returns the time until n units accumulate in the slowest window.
+func (r *RateLimiter) TryN(n int) (bool, time.Duration) {
+	now := time.Now()
+	res := r.ReserveN(now, n)
+	if !res.OK() {
+		return false, 0
+	}
 
-	// Create a timer.
-	timer := time.NewTimer(waitTime)
+	if delay := res.DelayFrom(now); delay > 0 {
+		res.CancelAt(now)
+		return false, delay
+	}
 
-	defer timer.Stop()
+	return true, 0
+}
 
+// Try gets the limit without blocking.
+// Returns true/false depending on if the limit is available.
+// If not, returns the time until enough tokens accumulate in the slowest window.
+func (r *RateLimiter) Try() (bool, time.Duration) {
+	return r.TryN(1)
+}
+
+// WaitN blocks until n events may happen across every window, or ctx is done.
+// If ctx is canceled first, the reserved tokens are returned to every window
+// so the canceled wait doesn't waste budget. If SleepAndReset fires while this
+// call is blocked, it reevaluates against the new pause/parameters instead of
+// waiting out the stale reservation. Concurrent callers are woken in arrival
+// order through a FIFO queue rather than each racing its own timer.
+func (r *RateLimiter) WaitN(ctx context.Context, n int) error {
 	for {
+		// Mirrors x/time/rate.Limiter.WaitN: bail out before reserving anything
+		// if ctx is already done, instead of returning nil and keeping tokens
+		// an already-canceled caller never gets to use.
 		select {
-		case <-timer.C:
-			// After the time has reached, try to get the rate again.
-			r.mu.Lock()
-			if r.allowAndIncrement() {
-				r.mu.Unlock()
-				return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		r.mu.Lock()
+		wake := r.wake
+		pause := r.pausedUntil
+		if pause.After(time.Now()) {
+			r.mu.Unlock()
+			if err := sleepUntil(ctx, pause, wake); err != nil {
+				return err
 			}
+			continue
+		}
 
-			// If couldn't, reset the timer and run again.
-			waitTime = r.getMinWaitTime()
+		now := time.Now()
+		ok, windows, maxWait := r.reserveLockedAll(now, n)
+		if !ok {
 			r.mu.Unlock()
-			timer.Reset(waitTime)
+			return errors.New("gomultirate: burst exceeds limit")
+		}
 
-		case <-ctx.Done():
-			return ctx.Err()
+		res := &Reservation{ok: true, limiter: r, n: n, timeToAct: now.Add(maxWait), windows: windows}
+
+		if maxWait == 0 {
+			r.mu.Unlock()
+			return nil
+		}
+
+		// Enqueue in the same critical section as the reservation, so the FIFO
+		// queue order always matches the order tokens were actually reserved in.
+		w := &waiter{timeToAct: res.timeToAct, promoted: closedChan}
+		if r.queue.Len() > 0 {
+			w.promoted = make(chan struct{})
+		}
+		el := r.queue.PushBack(w)
+		r.mu.Unlock()
+
+		err := r.waitTurn(ctx, w, wake)
+		r.leaveQueue(el)
+
+		if err == nil {
+			return nil
+		}
+
+		res.CancelAt(time.Now())
+		if err != errReconfigured {
+			return err
 		}
 	}
 }
 
-// WaitEvenly waits for all the limit windows to be available.
-// Get the limits at a fixed ratio based on the limit key.
-// Usefull if don't need to have a burst of usage.
-func (r *RateLimiter) WaitEvenly(ctx context.Context, key string) error {
+// Wait waits for all the limit windows to be available.
+// Receive a context for handling timeouts.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	return r.WaitN(ctx, 1)
+}
+
+// WaitEvenlyN waits until the window identified by 'key' is free, then reserves n
+// evenly-paced slots at once, e.g. for a batch request that counts as n units.
+// Future callers are spaced out by n*timeBetween instead of timeBetween, so a costly
+// call doesn't crowd out the slots it consumed. It draws from the same token balance
+// as Try/Wait/AllowN/ReserveN, so mixing WaitEvenly with those calling styles on one
+// window still shares a single real budget instead of doubling it. If SleepAndReset
+// fires while this call is blocked, it reevaluates against the new pause/parameters
+// instead of waiting out the stale slot.
+func (r *RateLimiter) WaitEvenlyN(ctx context.Context, key string, n int) error {
 	for {
 		r.mu.Lock()
+		wake := r.wake
+		pause := r.pausedUntil
+		now := time.Now()
+
+		if pause.After(now) {
+			r.mu.Unlock()
+			if err := sleepUntil(ctx, pause, wake); err != nil {
+				return err
+			}
+			continue
+		}
 
-		// Check if the limit exists
 		lim, exists := r.limits[key]
 		if !exists {
 			r.mu.Unlock()
 			return errors.New("limiter doesn't exist")
 		}
 
-		now := time.Now()
-
-		// Reset if interval has passed.
-		if now.Sub(lim.lastReset) >= lim.interval {
-			lim.count = 0
-			lim.lastReset = now
+		if lim.rate != Inf && n > lim.burst {
+			r.mu.Unlock()
+			return errors.New("gomultirate: n exceeds the window's limit")
 		}
 
-		// If under the limit, proceed.
-		if lim.count < lim.limit {
-			// Calculate the next timing.
-			nextTime := lim.lastReset.Add(lim.timeBetween * time.Duration(lim.count))
-			waitTime := nextTime.Sub(now)
+		// Consume from the same token balance Try/Wait/AllowN/ReserveN draw from.
+		_, tokenWait := lim.reserveLocked(now, n)
 
-			// Increment the counter before waiting (If necessary)
-			lim.count++
-			r.mu.Unlock()
+		// On top of the real budget, keep calls spaced n*timeBetween apart so a
+		// burst of WaitEvenly callers doesn't all pile onto the same instant the
+		// token bucket would otherwise allow.
+		if lim.nextEven.Before(now) {
+			lim.nextEven = now
+		}
+		evenWait := lim.nextEven.Sub(now)
+		lim.nextEven = lim.nextEven.Add(lim.timeBetween * time.Duration(n))
 
-			// Wait if needed (Distribute evenly)
-			if waitTime > 0 {
-				timer := time.NewTimer(waitTime)
-				select {
-				case <-timer.C:
-					// We've waited long enough, return success
-					return nil
-				case <-ctx.Done():
-					timer.Stop()
-					return ctx.Err()
-				}
-			}
+		waitTime := tokenWait
+		if evenWait > waitTime {
+			waitTime = evenWait
+		}
+		r.mu.Unlock()
 
+		if waitTime <= 0 {
 			return nil
 		}
 
-		// We're at the limit, calculate time until reset
-		waitTime := max(lim.interval-now.Sub(lim.lastReset), 0)
-
-		// Unlock since it will wait.
-		r.mu.Unlock()
-
-		// Wait for the reset time
 		timer := time.NewTimer(waitTime)
 		select {
 		case <-timer.C:
-			// Continue the loop to try again.
 			timer.Stop()
+			return nil
 		case <-ctx.Done():
 			timer.Stop()
+			r.mu.Lock()
+			lim.restoreLocked(time.Now(), n)
+			lim.nextEven = lim.nextEven.Add(-lim.timeBetween * time.Duration(n))
+			r.mu.Unlock()
 			return ctx.Err()
+		case <-wake:
+			timer.Stop()
+			r.mu.Lock()
+			lim.restoreLocked(time.Now(), n)
+			lim.nextEven = lim.nextEven.Add(-lim.timeBetween * time.Duration(n))
+			r.mu.Unlock()
 		}
 	}
 }
+
+// WaitEvenly waits until the window identified by 'key' is free, spacing calls evenly
+// at 'timeBetween' apart instead of allowing a burst.
+// Usefull if don't need to have a burst of usage.
+func (r *RateLimiter) WaitEvenly(ctx context.Context, key string) error {
+	return r.WaitEvenlyN(ctx, key, 1)
+}
+
+// WindowStats is a snapshot of a single window's token balance.
+type WindowStats struct {
+	// Tokens is the current token count, which may be negative if callers are
+	// already in debt waiting on a Reservation.
+	Tokens float64
+	// NextAvailable is the time at which at least one token will be available.
+	// It's time.Now() when a token is already available.
+	NextAvailable time.Time
+}
+
+// stats returns a snapshot of every window's current token count and next-available
+// time, as of now.
+func (r *RateLimiter) stats() map[string]WindowStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	out := make(map[string]WindowStats, len(r.limits))
+	for name, lim := range r.limits {
+		tokens := lim.advance(now)
+
+		next := now
+		if tokens < 1 {
+			next = now.Add(time.Duration((1 - tokens) / float64(lim.rate) * float64(time.Second)))
+		}
+
+		out[name] = WindowStats{Tokens: tokens, NextAvailable: next}
+	}
+	return out
+}
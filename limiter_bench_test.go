@@ -0,0 +1,103 @@
+package gomultirate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// legacyWait reproduces the timer-per-waiter Wait this package used before the FIFO
+// wakeup queue (chunk0-6): every blocked caller reserves independently and arms its
+// own timer, retrying on wake instead of taking a turn in a shared queue. Kept here
+// only so the benchmarks below have a real before/after to compare against.
+func legacyWait(r *RateLimiter, ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		wake := r.wake
+		pause := r.pausedUntil
+		r.mu.Unlock()
+
+		if now := time.Now(); pause.After(now) {
+			if err := sleepUntil(ctx, pause, wake); err != nil {
+				return err
+			}
+			continue
+		}
+
+		now := time.Now()
+		res := r.ReserveN(now, 1)
+		if !res.OK() {
+			return errors.New("gomultirate: burst exceeds limit")
+		}
+
+		delay := res.DelayFrom(now)
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+			timer.Stop()
+			return nil
+		case <-ctx.Done():
+			timer.Stop()
+			res.CancelAt(time.Now())
+			return ctx.Err()
+		case <-wake:
+			timer.Stop()
+			res.CancelAt(time.Now())
+		}
+	}
+}
+
+// benchmarkWaitConcurrency spins up 'concurrency' goroutines that each call 'wait'
+// once on a limiter whose window refills fast enough that contention, not the rate
+// itself, dominates the measurement.
+func benchmarkWaitConcurrency(b *testing.B, concurrency int, wait func(*RateLimiter, context.Context) error) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Microsecond, 1),
+	}
+
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		limiter, _ := NewRateLimiter(limits)
+
+		var wg sync.WaitGroup
+		wg.Add(concurrency)
+		for j := 0; j < concurrency; j++ {
+			go func() {
+				defer wg.Done()
+				_ = wait(limiter, ctx)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkWaitFIFO_100Waiters(b *testing.B) {
+	benchmarkWaitConcurrency(b, 100, (*RateLimiter).Wait)
+}
+
+func BenchmarkWaitFIFO_1000Waiters(b *testing.B) {
+	benchmarkWaitConcurrency(b, 1000, (*RateLimiter).Wait)
+}
+
+func BenchmarkWaitFIFO_10000Waiters(b *testing.B) {
+	benchmarkWaitConcurrency(b, 10000, (*RateLimiter).Wait)
+}
+
+func BenchmarkWaitLegacy_100Waiters(b *testing.B) {
+	benchmarkWaitConcurrency(b, 100, legacyWait)
+}
+
+func BenchmarkWaitLegacy_1000Waiters(b *testing.B) {
+	benchmarkWaitConcurrency(b, 1000, legacyWait)
+}
+
+func BenchmarkWaitLegacy_10000Waiters(b *testing.B) {
+	benchmarkWaitConcurrency(b, 10000, legacyWait)
+}
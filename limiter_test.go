@@ -2,6 +2,7 @@ package gomultirate
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 )
@@ -93,7 +94,7 @@ func TestWaitTimeout(t *testing.T) {
 
 	limiter, _ := NewRateLimiter(limits)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
 
 	limiter.Wait(ctx)
@@ -103,3 +104,274 @@ func TestWaitTimeout(t *testing.T) {
 	}
 
 }
+
+// Test AllowN against a burst, verifying it doesn't partially consume windows.
+func TestAllowN(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Second, 4),
+	}
+
+	limiter, _ := NewRateLimiter(limits)
+
+	if !limiter.AllowN(3) {
+		t.Error("expected AllowN(3) to succeed on a fresh window of burst 4")
+	}
+
+	if limiter.AllowN(2) {
+		t.Error("expected AllowN(2) to fail with only 1 token left")
+	}
+
+	if !limiter.AllowN(1) {
+		t.Error("expected AllowN(1) to succeed with 1 token left")
+	}
+}
+
+// Test that canceling a reservation returns its tokens instead of wasting them.
+func TestReservationCancel(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Second, 1),
+	}
+
+	limiter, _ := NewRateLimiter(limits)
+
+	now := time.Now()
+	res := limiter.ReserveN(now, 1)
+	if !res.OK() || res.DelayFrom(now) != 0 {
+		t.Fatal("expected the first reservation to be immediately available")
+	}
+
+	// Reserving again goes into debt; canceling it should give the tokens back.
+	res2 := limiter.ReserveN(now, 1)
+	if res2.DelayFrom(now) == 0 {
+		t.Fatal("expected the second reservation to require a wait")
+	}
+	res2.CancelAt(now)
+
+	if tokens := limiter.stats()["test"].Tokens; tokens < -0.5 {
+		t.Errorf("expected the canceled reservation's token to be returned, balance is %v", tokens)
+	}
+
+	if ok, _ := limiter.Try(); ok {
+		t.Error("expected Try to fail right after a single token was consumed")
+	}
+}
+
+// Test TryN consuming more than one unit per call, and rejecting an impossible n.
+func TestTryN(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Second, 4),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	if ok, _ := limiter.TryN(3); !ok {
+		t.Error("expected TryN(3) to succeed on a fresh window of burst 4")
+	}
+	if ok, _ := limiter.TryN(2); ok {
+		t.Error("expected TryN(2) to fail with only 1 token left")
+	}
+	if ok, wait := limiter.TryN(10); ok || wait != 0 {
+		t.Error("expected TryN(10) to fail immediately, 10 exceeds the window's burst")
+	}
+}
+
+// Test that WaitEvenlyN spaces a weighted call out by n*timeBetween.
+func TestWaitEvenlyN(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Second, 10),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	ctx := context.Background()
+
+	start := time.Now()
+	if err := limiter.WaitEvenlyN(ctx, "test", 3); err != nil {
+		t.Fatalf("expected first WaitEvenlyN call to succeed immediately: %v", err)
+	}
+
+	if err := limiter.WaitEvenlyN(ctx, "test", 1); err != nil {
+		t.Fatalf("WaitEvenlyN failed: %v", err)
+	}
+
+	elapsed := time.Since(start)
+	expected := 3 * (time.Second / 10) // n*timeBetween from the first call
+	delta := elapsed - expected
+	if delta < -50*time.Millisecond || delta > 50*time.Millisecond {
+		t.Errorf("expected the next slot ~%v after a weight-3 call, got %v", expected, elapsed)
+	}
+}
+
+// Test that WaitEvenly shares its budget with Try/Wait on the same window, instead
+// of tracking an independent token balance that would let a caller mixing both
+// calling styles double their real throughput.
+func TestWaitEvenlySharesBudgetWithTry(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Hour, 1),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	if ok, _ := limiter.Try(); !ok {
+		t.Fatal("expected the first Try to succeed")
+	}
+	if ok, _ := limiter.Try(); ok {
+		t.Fatal("expected the second Try to fail, the only token is spent")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.WaitEvenly(ctx, "test"); err == nil {
+		t.Error("expected WaitEvenly to block on the same exhausted budget Try just spent, not return immediately")
+	}
+}
+
+// Test that Wait returns ctx.Err() instead of nil when ctx is already canceled
+// before the call, even though a token is free and no real wait is needed.
+func TestWaitAlreadyCanceled(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Second, 1),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := limiter.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to fail immediately on an already-canceled ctx")
+	}
+
+	if ok, _ := limiter.Try(); !ok {
+		t.Error("expected the token to still be available, Wait must not have consumed it")
+	}
+}
+
+// Test that a canceled WaitEvenly attempt doesn't permanently push the pacing
+// schedule forward, since nothing about that attempt actually happened.
+func TestWaitEvenlyCancelDoesNotAdvanceSchedule(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Second, 10), // timeBetween = 100ms
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	ctx := context.Background()
+	if err := limiter.WaitEvenly(ctx, "test"); err != nil {
+		t.Fatalf("expected first WaitEvenly call to succeed immediately: %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.WaitEvenly(canceled, "test"); err == nil {
+		t.Fatal("expected the second WaitEvenly call to fail on an already-canceled ctx")
+	}
+
+	start := time.Now()
+	if err := limiter.WaitEvenly(ctx, "test"); err != nil {
+		t.Fatalf("WaitEvenly failed: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	expected := 100 * time.Millisecond
+	delta := elapsed - expected
+	if delta < -30*time.Millisecond || delta > 30*time.Millisecond {
+		t.Errorf("expected the canceled attempt to leave the schedule untouched, wait ~%v, got %v", expected, elapsed)
+	}
+}
+
+// Test that SleepAndReset also pauses non-blocking calls (Try/TryN/AllowN), not
+// just blocking Wait callers, so a caller polling with Try right after a 429
+// doesn't immediately re-hit the endpoint.
+func TestSleepAndResetPausesTry(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(time.Millisecond, 1),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	if err := limiter.SleepAndReset(200*time.Millisecond, "test", time.Millisecond, 1); err != nil {
+		t.Fatalf("SleepAndReset failed: %v", err)
+	}
+
+	if ok, _ := limiter.Try(); ok {
+		t.Error("expected Try to stay paused right after SleepAndReset, even though the window was just refilled")
+	}
+	if limiter.AllowN(1) {
+		t.Error("expected AllowN to stay paused right after SleepAndReset")
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if ok, _ := limiter.Try(); !ok {
+		t.Error("expected Try to succeed once the pause elapsed")
+	}
+}
+
+// Test that SleepAndReset pauses a blocked Wait and wakes it up against the new
+// parameters instead of letting it sleep out the stale, much longer delay.
+func TestSleepAndReset(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(10*time.Second, 1),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	ctx := context.Background()
+	_ = limiter.Wait(ctx) // consume the only token; the next Wait would sleep ~10s
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		done <- limiter.Wait(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := limiter.SleepAndReset(100*time.Millisecond, "test", 200*time.Millisecond, 1); err != nil {
+		t.Fatalf("SleepAndReset failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Wait should have recalibrated against the new limit quickly, took %v", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait never returned after SleepAndReset")
+	}
+}
+
+// Test that blocked Wait callers are released in arrival order, not whichever
+// goroutine happens to win the race on wakeup.
+func TestWaitFIFOFairness(t *testing.T) {
+	limits := map[string]*Limit{
+		"test": NewLimit(50*time.Millisecond, 1),
+	}
+	limiter, _ := NewRateLimiter(limits)
+
+	ctx := context.Background()
+	_ = limiter.Wait(ctx) // consume the initial token so every goroutine below blocks
+
+	const n = 8
+	order := make(chan int, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := limiter.Wait(ctx); err != nil {
+				t.Errorf("Wait %d failed: %v", i, err)
+				return
+			}
+			order <- i
+		}(i)
+		time.Sleep(5 * time.Millisecond) // stagger so arrival order is well defined
+	}
+	wg.Wait()
+	close(order)
+
+	i := 0
+	for got := range order {
+		if got != i {
+			t.Fatalf("expected FIFO completion order, caller %d finished at position %d", got, i)
+		}
+		i++
+	}
+}
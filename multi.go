@@ -0,0 +1,160 @@
+package gomultirate
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Combinator selects how a MultiLimiter acquires from its child limiters.
+type Combinator int
+
+const (
+	// AllOf requires every child limiter to allow the event, the same way a single
+	// RateLimiter requires every one of its windows to allow it, but across several
+	// independent limiters that may have entirely different key sets.
+	AllOf Combinator = iota
+	// AnyOf acquires from the first child limiter that's available, useful for
+	// fallback pools (e.g. a primary and a backup rate-limited resource).
+	AnyOf
+)
+
+// MultiLimiter wraps several *RateLimiter instances and acquires from them together
+// using explicit AllOf/AnyOf semantics, instead of the caller manually nesting Wait
+// calls, which would double-charge windows on context cancellation.
+// Use NewMultiLimiter to create an instance.
+type MultiLimiter struct {
+	combinator Combinator
+	children   []*RateLimiter
+}
+
+// NewMultiLimiter creates a MultiLimiter over 'children' using 'combinator' to decide
+// how an acquisition is satisfied.
+func NewMultiLimiter(combinator Combinator, children ...*RateLimiter) (*MultiLimiter, error) {
+	if len(children) == 0 {
+		return nil, errors.New("can't provide a multi limiter with no children")
+	}
+
+	return &MultiLimiter{combinator: combinator, children: children}, nil
+}
+
+// Try reports whether the combinator is satisfied right now, without blocking.
+func (m *MultiLimiter) Try() bool {
+	if m.combinator == AnyOf {
+		for _, child := range m.children {
+			if ok, _ := child.Try(); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	now := time.Now()
+	reserved := make([]*Reservation, 0, len(m.children))
+	for _, child := range m.children {
+		res := child.ReserveN(now, 1)
+		if !res.OK() || res.DelayFrom(now) > 0 {
+			res.CancelAt(now)
+			for _, done := range reserved {
+				done.CancelAt(now)
+			}
+			return false
+		}
+		reserved = append(reserved, res)
+	}
+	return true
+}
+
+// Wait blocks until the combinator is satisfied or ctx is done.
+func (m *MultiLimiter) Wait(ctx context.Context) error {
+	if m.combinator == AnyOf {
+		return m.waitAnyOf(ctx)
+	}
+	return m.waitAllOf(ctx)
+}
+
+// waitAllOf reserves one token from every child up front, then waits out the slowest
+// child's delay. If ctx is done first, every reservation is rolled back so the
+// cancellation doesn't leave any child in debt.
+func (m *MultiLimiter) waitAllOf(ctx context.Context) error {
+	now := time.Now()
+	reservations := make([]*Reservation, 0, len(m.children))
+	var maxDelay time.Duration
+
+	for _, child := range m.children {
+		res := child.ReserveN(now, 1)
+		if !res.OK() {
+			for _, done := range reservations {
+				done.CancelAt(time.Now())
+			}
+			return errors.New("gomultirate: burst exceeds limit")
+		}
+
+		reservations = append(reservations, res)
+		if delay := res.DelayFrom(now); delay > maxDelay {
+			maxDelay = delay
+		}
+	}
+
+	if maxDelay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(maxDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		now = time.Now()
+		for _, res := range reservations {
+			res.CancelAt(now)
+		}
+		return ctx.Err()
+	}
+}
+
+// waitAnyOf races every child's Wait and returns as soon as one acquires, canceling
+// the rest via a derived context so they roll back their own reservations.
+func (m *MultiLimiter) waitAnyOf(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// If more than one child already has budget, racing their Wait calls as
+	// goroutines would let every immediately-available child acquire before any
+	// of them could observe the others' cancellation: a goroutine whose
+	// reservation needs no wait returns before childCtx is ever canceled, so
+	// the backup pool gets burned even though the primary was available too.
+	// Try each child up front, in order, and take the first that's free without
+	// ever spinning up the race.
+	for _, child := range m.children {
+		if ok, _ := child.Try(); ok {
+			return nil
+		}
+	}
+
+	childCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error, len(m.children))
+	for _, child := range m.children {
+		child := child
+		go func() {
+			results <- child.Wait(childCtx)
+		}()
+	}
+
+	var lastErr error
+	for range m.children {
+		err := <-results
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return lastErr
+}
@@ -0,0 +1,101 @@
+package gomultirate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test that AllOf requires every child to have budget.
+func TestMultiLimiterAllOf(t *testing.T) {
+	global, _ := NewRateLimiter(map[string]*Limit{"global": NewLimit(time.Second, 2)})
+	endpoint, _ := NewRateLimiter(map[string]*Limit{"endpoint": NewLimit(time.Second, 1)})
+
+	multi, err := NewMultiLimiter(AllOf, global, endpoint)
+	if err != nil {
+		t.Fatalf("NewMultiLimiter failed: %v", err)
+	}
+
+	if !multi.Try() {
+		t.Error("expected first Try to succeed, both children have budget")
+	}
+	if multi.Try() {
+		t.Error("expected second Try to fail, the endpoint child is exhausted")
+	}
+
+	// The global child should still have its budget back, since AllOf rolled
+	// back the reservation it made on the failed attempt.
+	if ok, _ := global.Try(); !ok {
+		t.Error("expected the global child's token to have been rolled back")
+	}
+}
+
+// Test that AnyOf succeeds as long as one child has budget.
+func TestMultiLimiterAnyOf(t *testing.T) {
+	exhausted, _ := NewRateLimiter(map[string]*Limit{"primary": NewLimit(time.Second, 1)})
+	exhausted.Try() // spend the only token
+
+	backup, _ := NewRateLimiter(map[string]*Limit{"backup": NewLimit(time.Second, 1)})
+
+	multi, err := NewMultiLimiter(AnyOf, exhausted, backup)
+	if err != nil {
+		t.Fatalf("NewMultiLimiter failed: %v", err)
+	}
+
+	if !multi.Try() {
+		t.Error("expected Try to succeed via the backup child")
+	}
+}
+
+// Test that AnyOf.Wait only spends one child's token when more than one child
+// is immediately available, instead of racing both and burning the backup too.
+func TestMultiLimiterAnyOfWaitDoesNotBurnBothWhenBothAvailable(t *testing.T) {
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		primary, _ := NewRateLimiter(map[string]*Limit{"primary": NewLimit(time.Second, 1)})
+		backup, _ := NewRateLimiter(map[string]*Limit{"backup": NewLimit(time.Second, 1)})
+
+		multi, err := NewMultiLimiter(AnyOf, primary, backup)
+		if err != nil {
+			t.Fatalf("NewMultiLimiter failed: %v", err)
+		}
+
+		if err := multi.Wait(ctx); err != nil {
+			t.Fatalf("expected Wait to succeed, both children are fresh: %v", err)
+		}
+
+		// Give any stray goroutine from a racy implementation a chance to run.
+		time.Sleep(20 * time.Millisecond)
+
+		primaryOK, _ := primary.Try()
+		backupOK, _ := backup.Try()
+		if !primaryOK && !backupOK {
+			t.Fatal("expected only one child to have been drained, both were")
+		}
+	}
+}
+
+// Test that Wait under AllOf rolls back every reservation when ctx is canceled.
+func TestMultiLimiterWaitAllOfCancel(t *testing.T) {
+	fast, _ := NewRateLimiter(map[string]*Limit{"fast": NewLimit(time.Second, 1)})
+	slow, _ := NewRateLimiter(map[string]*Limit{"slow": NewLimit(10*time.Second, 1)})
+
+	multi, _ := NewMultiLimiter(AllOf, fast, slow)
+
+	multi.Try() // spend both children's single token
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := multi.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to time out waiting on the slow child")
+	}
+
+	// fast's window refills within a second; it should not have been left in debt
+	// by the canceled attempt.
+	time.Sleep(1100 * time.Millisecond)
+	if ok, _ := fast.Try(); !ok {
+		t.Error("expected the fast child's reservation to have been rolled back on cancel")
+	}
+}